@@ -0,0 +1,89 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package algopts provides shared functional options for the scalar
+// multiplication and multi-scalar multiplication methods exposed by the
+// std/algebra curve implementations.
+package algopts
+
+import (
+	"fmt"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+)
+
+// Config collects the options configured via AlgebraOption. The zero
+// value selects the default, fastest behaviour of every method (no
+// completeness guarantee, no subgroup check, the curve's standard
+// generator as fixed base).
+type Config struct {
+	// CompleteArithmetic requests that scalar multiplication and
+	// multi-scalar multiplication be correct for every input, including
+	// the point at infinity and a zero scalar, at the cost of extra
+	// constraints for the conditional handling of those cases.
+	CompleteArithmetic bool
+	// AssertSubgroupQ requests that every input point be asserted to lie
+	// in the prime-order subgroup before use.
+	AssertSubgroupQ bool
+	// FixedBasePoint overrides the default generator used by
+	// ScalarMulFixedBase, for callers whose compile-time-known base is
+	// not the curve's standard generator.
+	FixedBasePoint *bls12377.G2Affine
+}
+
+// AlgebraOption allows configuring the behaviour of scalar multiplication
+// and multi-scalar multiplication methods.
+type AlgebraOption func(*Config) error
+
+// NewConfig applies the given options on top of the default Config and
+// returns the result.
+func NewConfig(opts ...AlgebraOption) (*Config, error) {
+	cfg := new(Config)
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// WithCompleteArithmetic requests the complete (constant-time-safe for
+// every input, including the point at infinity) variant of the method it
+// is passed to.
+func WithCompleteArithmetic() AlgebraOption {
+	return func(cfg *Config) error {
+		cfg.CompleteArithmetic = true
+		return nil
+	}
+}
+
+// WithSubgroupCheck requests that every point argument of the method it
+// is passed to be asserted to lie in the prime-order subgroup before use.
+func WithSubgroupCheck() AlgebraOption {
+	return func(cfg *Config) error {
+		cfg.AssertSubgroupQ = true
+		return nil
+	}
+}
+
+// WithFixedBase sets the compile-time-known point that ScalarMulFixedBase
+// multiplies, in place of the curve's standard generator.
+func WithFixedBase(point bls12377.G2Affine) AlgebraOption {
+	return func(cfg *Config) error {
+		cfg.FixedBasePoint = &point
+		return nil
+	}
+}
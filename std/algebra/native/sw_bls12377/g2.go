@@ -155,19 +155,16 @@ func (p *g2AffP) Double(api frontend.API, p1 g2AffP) *g2AffP {
 
 }
 
-func (P *g2AffP) doubleN(api frontend.API, Q *g2AffP, n int) *g2AffP {
-	pn := Q
-	for s := 0; s < n; s++ {
-		pn.Double(api, *pn)
-	}
-	return pn
-}
-
 func (P *g2AffP) scalarMulBySeed(api frontend.API, Q *g2AffP) *g2AffP {
-	var z, t0, t1 g2AffP
-	z.Double(api, *Q)
-	z.AddAssign(api, *Q)
-	z.DoubleAndAdd(api, &z, Q)
+	// The inner accumulator is kept in projective coordinates (g2ProjP) so
+	// that every Double/AddAssign/DoubleAndAdd step below is a complete
+	// formula using only multiplications, with a single shared inversion
+	// to get back to affine at the very end.
+	var qP, z, t0, t1 g2ProjP
+	qP.FromAffine(api, *Q)
+	z.Double(api, qP)
+	z.AddAssign(api, qP)
+	z.DoubleAndAdd(api, &z, &qP)
 	t0.Double(api, z)
 	t0.Double(api, t0)
 	z.AddAssign(api, t0)
@@ -177,7 +174,9 @@ func (P *g2AffP) scalarMulBySeed(api frontend.API, Q *g2AffP) *g2AffP {
 	t0.doubleN(api, &t0, 9)
 	z.DoubleAndAdd(api, &t0, &z)
 	z.doubleN(api, &z, 45)
-	P.DoubleAndAdd(api, &z, Q)
+	var res g2ProjP
+	res.DoubleAndAdd(api, &z, &qP)
+	res.toAffine(api, P)
 
 	return P
 }
@@ -226,6 +225,11 @@ func (P *g2AffP) varScalarMul(api frontend.API, Q g2AffP, s frontend.Variable, o
 	if err != nil {
 		panic(err)
 	}
+	if cfg.AssertSubgroupQ {
+		// the caller opted into validating that the input point lies in the
+		// prime-order subgroup before it is used below.
+		Q.assertIsInSubGroup(api)
+	}
 	// This method computes [s] Q. We use several methods to reduce the number
 	// of added constraints - first, instead of classical double-and-add, we use
 	// the optimized version from https://github.com/zcash/zcash/issues/3924
@@ -293,6 +297,13 @@ func (P *g2AffP) varScalarMul(api frontend.API, Q g2AffP, s frontend.Variable, o
 	Acc = tableQ[1]
 	Acc.AddAssign(api, tablePhiQ[1])
 
+	// From here on the accumulator is kept in projective coordinates
+	// (g2ProjP): every Double/DoubleAndAdd below becomes a complete formula
+	// with no E2 inversion, and we normalize back to affine once, right
+	// before the final conditional step.
+	var accP g2ProjP
+	accP.FromAffine(api, Acc)
+
 	// However, we can not directly add step value conditionally as we may get
 	// to incomplete path of the addition formula. We either add or subtract
 	// step value from [2] Acc (instead of conditionally adding step value to
@@ -301,18 +312,18 @@ func (P *g2AffP) varScalarMul(api frontend.API, Q g2AffP, s frontend.Variable, o
 	// only y coordinate differs for negation, select on that instead.
 	B.X = tableQ[0].X
 	B.Y.Select(api, s1bits[nbits-1], tableQ[1].Y, tableQ[0].Y)
-	Acc.DoubleAndAdd(api, &Acc, &B)
+	accP.MixedDoubleAndAdd(api, &accP, &B)
 	B.X = tablePhiQ[0].X
 	B.Y.Select(api, s2bits[nbits-1], tablePhiQ[1].Y, tablePhiQ[0].Y)
-	Acc.AddAssign(api, B)
+	accP.AddAffine(api, B)
 
 	// second bit
 	B.X = tableQ[0].X
 	B.Y.Select(api, s1bits[nbits-2], tableQ[1].Y, tableQ[0].Y)
-	Acc.DoubleAndAdd(api, &Acc, &B)
+	accP.MixedDoubleAndAdd(api, &accP, &B)
 	B.X = tablePhiQ[0].X
 	B.Y.Select(api, s2bits[nbits-2], tablePhiQ[1].Y, tablePhiQ[0].Y)
-	Acc.AddAssign(api, B)
+	accP.AddAffine(api, B)
 
 	B2.X = tablePhiQ[0].X
 	for i := nbits - 3; i > 0; i-- {
@@ -320,9 +331,11 @@ func (P *g2AffP) varScalarMul(api frontend.API, Q g2AffP, s frontend.Variable, o
 		B.Y.Select(api, s1bits[i], tableQ[1].Y, tableQ[0].Y)
 		B2.Y.Select(api, s2bits[i], tablePhiQ[1].Y, tablePhiQ[0].Y)
 		B.AddAssign(api, B2)
-		Acc.DoubleAndAdd(api, &Acc, &B)
+		accP.MixedDoubleAndAdd(api, &accP, &B)
 	}
 
+	accP.toAffine(api, &Acc)
+
 	// i = 0
 	// When cfg.CompleteArithmetic is set, we use AddUnified instead of Add. This means
 	// when s=0 then Acc=(0,0) because AddUnified(Q, -Q) = (0,0).
@@ -352,6 +365,9 @@ func (P *g2AffP) constScalarMul(api frontend.API, Q g2AffP, s *big.Int, opts ...
 	if err != nil {
 		panic(err)
 	}
+	if cfg.AssertSubgroupQ {
+		Q.assertIsInSubGroup(api)
+	}
 	if s.BitLen() == 0 {
 		zero := fields_bls12377.E2{A0: 0, A1: 0}
 		P.X = zero
@@ -412,13 +428,22 @@ func (P *g2AffP) constScalarMul(api frontend.API, Q g2AffP, s *big.Int, opts ...
 		}
 		nbits = nbits - 1
 	}
-	for i := nbits - 1; i > 0; i-- {
-		if cfg.CompleteArithmetic {
+	if cfg.CompleteArithmetic {
+		for i := nbits - 1; i > 0; i-- {
 			Acc.AddUnified(api, Acc)
 			Acc.AddUnified(api, table[k[0].Bit(i)+2*k[1].Bit(i)])
-		} else {
-			Acc.DoubleAndAdd(api, &Acc, &table[k[0].Bit(i)+2*k[1].Bit(i)])
 		}
+	} else {
+		// the main loop is the hot path of this function, so the accumulator
+		// is kept in projective coordinates to replace the E2 inversion
+		// hidden in every DoubleAndAdd by complete multiplications, only
+		// normalizing back to affine once the loop is done.
+		var accP g2ProjP
+		accP.FromAffine(api, Acc)
+		for i := nbits - 1; i > 0; i-- {
+			accP.MixedDoubleAndAdd(api, &accP, &table[k[0].Bit(i)+2*k[1].Bit(i)])
+		}
+		accP.toAffine(api, &Acc)
 	}
 
 	// i = 0
@@ -449,44 +474,6 @@ func (p *g2AffP) AssertIsEqual(api frontend.API, other g2AffP) {
 	p.Y.AssertIsEqual(api, other.Y)
 }
 
-// DoubleAndAdd computes 2*p1+p2 in affine coords
-func (p *g2AffP) DoubleAndAdd(api frontend.API, p1, p2 *g2AffP) *g2AffP {
-
-	var n, d, l1, l2, x3, x4, y4 fields_bls12377.E2
-
-	// compute lambda1 = (y2-y1)/(x2-x1)
-	n.Sub(api, p1.Y, p2.Y)
-	d.Sub(api, p1.X, p2.X)
-	l1.DivUnchecked(api, n, d)
-
-	// compute x3 = lambda1**2-x1-x2
-	x3.Square(api, l1).
-		Sub(api, x3, p1.X).
-		Sub(api, x3, p2.X)
-
-	// omit y3 computation
-	// compute lambda2 = -lambda1-2*y1/(x3-x1)
-	n.Double(api, p1.Y)
-	d.Sub(api, x3, p1.X)
-	l2.DivUnchecked(api, n, d)
-	l2.Add(api, l2, l1).Neg(api, l2)
-
-	// compute x4 =lambda2**2-x1-x3
-	x4.Square(api, l2).
-		Sub(api, x4, p1.X).
-		Sub(api, x4, x3)
-
-	// compute y4 = lambda2*(x1 - x4)-y1
-	y4.Sub(api, p1.X, x4).
-		Mul(api, l2, y4).
-		Sub(api, y4, p1.Y)
-
-	p.X = x4
-	p.Y = y4
-
-	return p
-}
-
 // ScalarMulBase computes s * g2 and returns it, where g2 is the fixed generator. It doesn't modify s.
 func (P *g2AffP) ScalarMulBase(api frontend.API, s frontend.Variable) *g2AffP {
 
@@ -525,19 +512,24 @@ func (P *g2AffP) ScalarMulBase(api frontend.API, s frontend.Variable) *g2AffP {
 			A0: points.G2m[2][2],
 			A1: points.G2m[2][3]})
 
+	// the accumulator is kept in projective coordinates over the 250
+	// remaining windows, so each step adds a compile-time constant point
+	// without an E2 inversion; we only come back to affine once, below.
+	var resP, tmpP g2ProjP
+	resP.FromAffine(api, res)
 	for i := 3; i < 253; i++ {
 		// gm[i] = [2^i]g
-		tmp.X = res.X
-		tmp.Y = res.Y
-		tmp.AddAssign(api, g2AffP{
+		tmpP = resP
+		tmpP.AddAffine(api, g2AffP{
 			fields_bls12377.E2{
 				A0: points.G2m[i][0],
 				A1: points.G2m[i][1]},
 			fields_bls12377.E2{
 				A0: points.G2m[i][2],
 				A1: points.G2m[i][3]}})
-		res.Select(api, sBits[i], tmp, res)
+		resP.Select(api, sBits[i], tmpP, resP)
 	}
+	resP.toAffine(api, &res)
 
 	// i = 0
 	tmp.Neg(api, g2AffP{
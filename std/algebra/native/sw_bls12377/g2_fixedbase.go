@@ -0,0 +1,257 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/native/fields_bls12377"
+)
+
+// fixedBaseCombWidth is the width w, in bits, of the windows used by the
+// fixed-base comb tables below: each window is resolved with a single
+// 2^w-entry lookup instead of w conditional additions.
+const fixedBaseCombWidth = 4
+
+// g2CombTable is a precomputed comb table for a fixed G2 point: for every
+// window i, table[i][k] = k·(2^(w·i))·point. Entry 0 of every window is
+// unused (a digit of 0 contributes nothing to the scalar multiplication
+// and is instead handled by selecting the identity) and is populated
+// with a copy of entry 1 so that every window is a dense power-of-two
+// sized table.
+type g2CombTable [][16]g2AffP
+
+// combTableCacheCap bounds the number of distinct base points
+// combTableCache keeps comb tables for. algopts.WithFixedBase lets a
+// caller supply an arbitrary compile-time-known point (e.g. a per-circuit
+// verifying key's β·G2), so without a cap a long-running process that
+// compiles circuits against many distinct points would grow this cache
+// forever. Once the cap is reached, the least-recently-built entry is
+// evicted to make room for the new one.
+const combTableCacheCap = 32
+
+var (
+	combTableCache      = map[bls12377.G2Affine][2]g2CombTable{}
+	combTableCacheOrder []bls12377.G2Affine
+	combTableCacheMu    sync.Mutex
+)
+
+// psiCoeffX, psiCoeffY are the native (outside-the-circuit) counterparts
+// of the two Frobenius coefficients used by g2AffP.psi.
+var (
+	psiCoeffX fp.Element
+	psiCoeffY fp.Element
+)
+
+func init() {
+	psiCoeffX.SetString("80949648264912719408558363140637477264845294720710499478137287262712535938301461879813459410946")
+	psiCoeffY.SetString("216465761340224619389371505802605247630151569547285782856803747159100223055385581585702401816380679166954762214499")
+}
+
+// nativePsi applies the same untwist-Frobenius-twist endomorphism as
+// g2AffP.psi, natively.
+func nativePsi(q bls12377.G2Affine) bls12377.G2Affine {
+	var res bls12377.G2Affine
+	res.X.Conjugate(&q.X)
+	res.X.MulByElement(&res.X, &psiCoeffX)
+	res.Y.Conjugate(&q.Y)
+	res.Y.MulByElement(&res.Y, &psiCoeffY)
+	return res
+}
+
+// getG2CombTable returns the pair of comb tables (for point and for
+// Φ(point)) used by ScalarMulFixedBase, building and caching them on
+// first use for a given point.
+func getG2CombTable(point bls12377.G2Affine) [2]g2CombTable {
+	combTableCacheMu.Lock()
+	defer combTableCacheMu.Unlock()
+	if t, ok := combTableCache[point]; ok {
+		return t
+	}
+
+	// the inner-curve config is keyed by the outer curve's scalar field,
+	// as in every in-circuit caller.
+	cc := getInnerCurveConfig(ecc.BW6_761.ScalarField())
+	nbits := cc.lambda.BitLen() + 1
+	nWindows := (nbits + fixedBaseCombWidth - 1) / fixedBaseCombWidth
+
+	build := func(base bls12377.G2Affine) g2CombTable {
+		table := make(g2CombTable, nWindows)
+		var windowBaseJac bls12377.G2Jac
+		windowBaseJac.FromAffine(&base)
+		for i := 0; i < nWindows; i++ {
+			var acc bls12377.G2Jac
+			acc.Set(&windowBaseJac)
+			var accAff bls12377.G2Affine
+			for k := 1; k < 16; k++ {
+				accAff.FromJacobian(&acc)
+				table[i][k] = g2AffP{
+					X: fields_bls12377.E2{A0: accAff.X.A0.BigInt(new(big.Int)), A1: accAff.X.A1.BigInt(new(big.Int))},
+					Y: fields_bls12377.E2{A0: accAff.Y.A0.BigInt(new(big.Int)), A1: accAff.Y.A1.BigInt(new(big.Int))},
+				}
+				acc.AddAssign(&windowBaseJac)
+			}
+			table[i][0] = table[i][1]
+			for b := 0; b < fixedBaseCombWidth; b++ {
+				windowBaseJac.Double(&windowBaseJac)
+			}
+		}
+		return table
+	}
+
+	// Φ (phi2, as in varScalarMul) acts on the r-torsion as [λ], so the
+	// table for Φ(point) is built from [λ]·point.
+	var phiPoint bls12377.G2Affine
+	phiPoint.ScalarMultiplication(&point, cc.lambda)
+	res := [2]g2CombTable{build(point), build(phiPoint)}
+
+	if len(combTableCacheOrder) >= combTableCacheCap {
+		oldest := combTableCacheOrder[0]
+		combTableCacheOrder = combTableCacheOrder[1:]
+		delete(combTableCache, oldest)
+	}
+	combTableCache[point] = res
+	combTableCacheOrder = append(combTableCacheOrder, point)
+	return res
+}
+
+// lookupE2 is a power-of-two-sized multiplexer: it returns values[idx]
+// where idx is the integer represented by bits (bits[0] is the
+// least-significant bit), using a binary tree of E2 selects.
+func lookupE2(api frontend.API, bits []frontend.Variable, values []fields_bls12377.E2) fields_bls12377.E2 {
+	if len(values) == 1 {
+		return values[0]
+	}
+	half := len(values) / 2
+	lo := lookupE2(api, bits[:len(bits)-1], values[:half])
+	hi := lookupE2(api, bits[:len(bits)-1], values[half:])
+	var res fields_bls12377.E2
+	res.Select(api, bits[len(bits)-1], hi, lo)
+	return res
+}
+
+// lookupG2 multiplexes a g2AffP out of table using bits, the binary
+// representation of the lookup index (least-significant bit first).
+func lookupG2(api frontend.API, bits []frontend.Variable, table [16]g2AffP) g2AffP {
+	xs := make([]fields_bls12377.E2, len(table))
+	ys := make([]fields_bls12377.E2, len(table))
+	for i := range table {
+		xs[i] = table[i].X
+		ys[i] = table[i].Y
+	}
+	return g2AffP{X: lookupE2(api, bits, xs), Y: lookupE2(api, bits, ys)}
+}
+
+// ScalarMulFixedBase sets P = [s]·basePoint and returns P, where
+// basePoint is the generator of G2 by default, or the point supplied via
+// algopts.WithFixedBase when the caller needs the same speedup for a
+// different compile-time-known point (e.g. β·G2 of a Groth16 verifying
+// key). Each distinct basePoint gets its own pair of comb tables, kept in
+// combTableCache (capped at combTableCacheCap entries, oldest-built
+// evicted first), so calling this with many distinct WithFixedBase points
+// evicts older tables instead of growing the cache without bound.
+//
+// Unlike ScalarMulBase's plain double-and-add, this consumes the same
+// GLV decomposition as varScalarMul to halve the number of bits to
+// process, and resolves every fixedBaseCombWidth-bit window of each half
+// with a single table lookup instead of a chain of conditional doubles,
+// bringing the number of conditional additions down from 253 to about
+// 2 * ceil(nbits/fixedBaseCombWidth).
+func (P *g2AffP) ScalarMulFixedBase(api frontend.API, s frontend.Variable, opts ...algopts.AlgebraOption) *g2AffP {
+	cfg, err := algopts.NewConfig(opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	_, _, _, basePoint := bls12377.Generators()
+	if cfg.FixedBasePoint != nil {
+		basePoint = *cfg.FixedBasePoint
+	}
+	tables := getG2CombTable(basePoint)
+
+	cc := getInnerCurveConfig(api.Compiler().Field())
+	sd, err := api.Compiler().NewHint(DecomposeScalarG2, 3, s)
+	if err != nil {
+		panic(err)
+	}
+	s1, s2 := sd[0], sd[1]
+	api.AssertIsEqual(api.Add(s1, api.Mul(s2, cc.lambda)), api.Add(s, api.Mul(cc.fr, sd[2])))
+
+	nbits := cc.lambda.BitLen() + 1
+	w := fixedBaseCombWidth
+	nWindows := (nbits + w - 1) / w
+	s1bits := api.ToBinary(s1, nbits)
+	s2bits := api.ToBinary(s2, nbits)
+
+	zero := fields_bls12377.E2{A0: 0, A1: 0}
+	one := fields_bls12377.E2{A0: 1, A1: 0}
+	identity := g2ProjP{X: zero, Y: one, Z: zero}
+
+	acc := identity
+	for win := nWindows - 1; win >= 0; win-- {
+		lo := win * w
+		hi := lo + w
+		if hi > nbits {
+			hi = nbits
+		}
+		b1 := make([]frontend.Variable, w)
+		b2 := make([]frontend.Variable, w)
+		digit1 := frontend.Variable(0)
+		digit2 := frontend.Variable(0)
+		coeff := 1
+		for j := 0; j < w; j++ {
+			if lo+j < hi {
+				b1[j] = s1bits[lo+j]
+				b2[j] = s2bits[lo+j]
+				digit1 = api.Add(digit1, api.Mul(b1[j], coeff))
+				digit2 = api.Add(digit2, api.Mul(b2[j], coeff))
+			} else {
+				b1[j] = 0
+				b2[j] = 0
+			}
+			coeff *= 2
+		}
+
+		e1 := lookupG2(api, b1, tables[0][win])
+		e2 := lookupG2(api, b2, tables[1][win])
+
+		var e1P, e2P g2ProjP
+		e1P.FromAffine(api, e1)
+		e1P.Select(api, api.IsZero(digit1), identity, e1P)
+		e2P.FromAffine(api, e2)
+		e2P.Select(api, api.IsZero(digit2), identity, e2P)
+
+		// every window's table is already scaled by 2^(w·win), so the
+		// window contributions are summed without doubling acc.
+		acc.AddAssign(api, e1P)
+		acc.AddAssign(api, e2P)
+	}
+
+	if cfg.CompleteArithmetic {
+		// the identity (Z=0) has no affine form: return the (0,0) sentinel.
+		acc.Select(api, acc.Z.IsZero(api), g2ProjP{X: zero, Y: zero, Z: one}, acc)
+	}
+	acc.toAffine(api, P)
+	return P
+}
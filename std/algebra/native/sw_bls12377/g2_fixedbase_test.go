@@ -0,0 +1,94 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/test"
+)
+
+type g2ScalarMulFixedBaseCircuit struct {
+	S        frontend.Variable
+	Expected G2Affine
+}
+
+func (c *g2ScalarMulFixedBaseCircuit) Define(api frontend.API) error {
+	var res g2AffP
+	res.ScalarMulFixedBase(api, c.S)
+	res.AssertIsEqual(api, c.Expected.P)
+	return nil
+}
+
+// TestG2ScalarMulFixedBase checks that ScalarMulFixedBase against the
+// default base point (the G2 generator) agrees with a native scalar
+// multiplication.
+func TestG2ScalarMulFixedBase(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+	s := big.NewInt(987654321)
+	var expected bls12377.G2Affine
+	expected.ScalarMultiplication(&g2Gen, s)
+
+	var witness g2ScalarMulFixedBaseCircuit
+	witness.S = s
+	witness.Expected.P.Assign(&expected)
+
+	assert.SolvingSucceeded(&g2ScalarMulFixedBaseCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+type g2ScalarMulFixedBaseWithOptCircuit struct {
+	basePoint bls12377.G2Affine
+	S         frontend.Variable
+	Expected  G2Affine
+}
+
+func (c *g2ScalarMulFixedBaseWithOptCircuit) Define(api frontend.API) error {
+	var res g2AffP
+	res.ScalarMulFixedBase(api, c.S, algopts.WithFixedBase(c.basePoint))
+	res.AssertIsEqual(api, c.Expected.P)
+	return nil
+}
+
+// TestG2ScalarMulFixedBaseWithOption checks that algopts.WithFixedBase
+// correctly substitutes an arbitrary compile-time-known point in place
+// of the default G2 generator.
+func TestG2ScalarMulFixedBaseWithOption(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+	var basePoint bls12377.G2Affine
+	basePoint.ScalarMultiplication(&g2Gen, big.NewInt(42))
+
+	s := big.NewInt(13)
+	var expected bls12377.G2Affine
+	expected.ScalarMultiplication(&basePoint, s)
+
+	circuit := g2ScalarMulFixedBaseWithOptCircuit{basePoint: basePoint}
+	witness := g2ScalarMulFixedBaseWithOptCircuit{basePoint: basePoint}
+	witness.S = s
+	witness.Expected.P.Assign(&expected)
+
+	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BW6_761))
+}
@@ -0,0 +1,367 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fptower"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/fields_bls12377"
+	"github.com/consensys/gnark/std/hash/sha2"
+)
+
+// The constants below are the parameters of the Shallue-van de Woestijne
+// (SVDW) map used by MapToCurveG2, applied directly to the twist of
+// BLS12-377 (E: y² = x³ + twistB, a=0), per RFC 9380 section 6.6.1/6.6.2.
+// Unlike the simplified SWU map, SVDW needs no isogenous curve and hence
+// no isogeny-map coefficients: it works on any curve of the form
+// y² = x³ + B.
+//
+// svdwZ was found by brute-force search over small elements of E2 for the
+// smallest Z satisfying Z≠0, g(Z)≠0 and -g(Z)·3Z² a square in E2 (the
+// conditions RFC 9380 appendix F.1's find_z_svdw requires for c3 to
+// exist); svdwC1..svdwC4 are then computed from Z as the RFC specifies.
+// nonResidueE2 is a fixed quadratic non-residue of the base field
+// (distinct from the field-tower non-residue used internally by
+// fields_bls12377.E2), used by sqrtCandidate's square/non-square trick.
+var (
+	svdwZ  = fields_bls12377.E2{A0: 2, A1: 0}
+	svdwC1 = fields_bls12377.E2{A0: 8, A1: "155198655607781456406391640216936120121836107652948796323930557600032281009004493664981332883744016074664192874906"}
+	svdwC2 = fields_bls12377.E2{A0: "258664426012969094010652733694893533536393512754914660539884262666720468348340822774968888139573360124440321458176", A1: 0}
+	svdwC3 = fields_bls12377.E2{A0: "176167996011041038227027268172818786419688168714579319282718903616278011457418412984061471725424117204123011379096", A1: "209524757051059563433482416591076552384687058796958840533487795092388893314652805681173445630970430848221848549578"}
+	svdwC4 = fields_bls12377.E2{A0: "172442950675312729340435155796595689024262341836609773693256175111146978898893881849979258759715573416293547638782", A1: "34488590135062545868087031159319137804852468367321954738651235022229395779778776369995851751943114683258709527757"}
+
+	nonResidueE2 = fields_bls12377.E2{A0: 0, A1: 1}
+)
+
+// HashToG2 hashes msg to a point in the prime-order subgroup of the twist
+// of BLS12-377, using dst as the RFC 9380 domain separation tag.
+//
+// It follows the standard construction for curves of j-invariant 0:
+// expand_message_xmd (over an in-circuit SHA-256) derives two candidate
+// field elements in E2, each is mapped to the twist via the SVDW map
+// (MapToCurveG2), the two resulting points are added together, and
+// finally the cofactor is cleared (ClearCofactor).
+func HashToG2(api frontend.API, msg []frontend.Variable, dst []byte) (G2Affine, error) {
+	u0, u1, err := hashToFieldE2(api, msg, dst)
+	if err != nil {
+		return G2Affine{}, err
+	}
+
+	var p0, p1 g2AffP
+	MapToCurveG2(api, &p0, u0)
+	MapToCurveG2(api, &p1, u1)
+	p0.AddUnified(api, p1)
+
+	var res G2Affine
+	res.P = p0
+	ClearCofactor(api, &res.P, &res.P)
+
+	return res, nil
+}
+
+// curveEval returns x³+twistB, i.e. the right-hand side of the twist's
+// curve equation Y² = X³ + twistB.
+func curveEval(api frontend.API, x fields_bls12377.E2) fields_bls12377.E2 {
+	var gx fields_bls12377.E2
+	gx.Square(api, x).Mul(api, gx, x)
+	gx.Add(api, gx, twistB)
+	return gx
+}
+
+// MapToCurveG2 maps the E2 field element u to a point p on the twist of
+// BLS12-377, using the Shallue-van de Woestijne map (RFC 9380 section
+// 6.6.2) with the constants above. It is exposed so that callers can
+// compose it with their own hash-to-field routine.
+func MapToCurveG2(api frontend.API, p *g2AffP, u fields_bls12377.E2) *g2AffP {
+	one := fields_bls12377.E2{A0: 1, A1: 0}
+
+	var tv1, tv2, tv3, tv4 fields_bls12377.E2
+	tv1.Square(api, u).Mul(api, tv1, svdwC1)
+	tv2.Add(api, one, tv1)
+	tv1.Sub(api, one, tv1)
+	tv3.Mul(api, tv1, tv2)
+	tv3 = invE2(api, tv3)
+	tv4.Mul(api, u, tv1).Mul(api, tv4, tv3).Mul(api, tv4, svdwC3)
+
+	var x1, gx1, x2, gx2, x3 fields_bls12377.E2
+	x1.Sub(api, svdwC2, tv4)
+	gx1 = curveEval(api, x1)
+	e1, _ := sqrtCandidate(api, gx1)
+
+	x2.Add(api, svdwC2, tv4)
+	gx2 = curveEval(api, x2)
+	e2raw, _ := sqrtCandidate(api, gx2)
+	e2 := api.And(e2raw, api.Sub(1, e1))
+
+	x3.Square(api, tv2).Mul(api, x3, tv3)
+	x3.Square(api, x3).Mul(api, x3, svdwC4)
+	x3.Add(api, x3, svdwZ)
+
+	var x fields_bls12377.E2
+	x.Select(api, e2, x2, x3)
+	x.Select(api, e1, x1, x)
+
+	gx := curveEval(api, x)
+	isSquare, root := sqrtCandidate(api, gx)
+	api.AssertIsEqual(isSquare, 1)
+	y := root
+
+	// constant-time sign fix-up: y must carry the same sgn0 as u
+	sgnU := sgn0(api, u)
+	sgnY := sgn0(api, y)
+	var negY fields_bls12377.E2
+	negY.Neg(api, y)
+	sameSign := api.IsZero(api.Sub(sgnU, sgnY))
+	y.Select(api, sameSign, y, negY)
+
+	p.X = x
+	p.Y = y
+
+	return p
+}
+
+// ClearCofactor sets res to [x²-x-1]·P + [x-1]·ψ(P) + ψ²([2]P), the
+// Budroni-Pintore cofactor-clearing formula for the twist of BLS12-377,
+// and returns res. It places a point on the r-torsion subgroup starting
+// from any point on the twist.
+func ClearCofactor(api frontend.API, res *g2AffP, P *g2AffP) *g2AffP {
+	var xP, x2P, x2mxm1P g2AffP
+	xP.scalarMulBySeed(api, P)
+	x2P.scalarMulBySeed(api, &xP)
+
+	// [x²-x-1]P = [x²]P - [x]P - P
+	var negXP, negP g2AffP
+	negXP.Neg(api, xP)
+	negP.Neg(api, *P)
+	x2mxm1P = x2P
+	x2mxm1P.AddUnified(api, negXP)
+	x2mxm1P.AddUnified(api, negP)
+
+	// [x-1]ψ(P) = [x]ψ(P) - ψ(P)
+	var psiP, xPsiP, negPsiP, xm1PsiP g2AffP
+	psiP.psi(api, P)
+	xPsiP.scalarMulBySeed(api, &psiP)
+	negPsiP.Neg(api, psiP)
+	xm1PsiP = xPsiP
+	xm1PsiP.AddUnified(api, negPsiP)
+
+	// ψ²([2]P)
+	var twoP, psi2 g2AffP
+	twoP.Double(api, *P)
+	psi2.psi(api, &twoP)
+	psi2.psi(api, &psi2)
+
+	sum := x2mxm1P
+	sum.AddUnified(api, xm1PsiP)
+	sum.AddUnified(api, psi2)
+
+	*res = sum
+	return res
+}
+
+// invE2 computes 1/x, returning the all-zero E2 element (per RFC 9380's
+// inv0 convention) when x=0.
+func invE2(api frontend.API, x fields_bls12377.E2) fields_bls12377.E2 {
+	var zero, inv fields_bls12377.E2
+	isZero := x.IsZero(api)
+	one := fields_bls12377.E2{A0: 1, A1: 0}
+	var safeX fields_bls12377.E2
+	safeX.Select(api, isZero, one, x)
+	inv.DivUnchecked(api, one, safeX)
+	inv.Select(api, isZero, zero, inv)
+	return inv
+}
+
+// sgn0 returns the RFC 9380 sign indicator of e (0 or 1): the parity of
+// A0 when it is non-zero, and the parity of A1 otherwise.
+func sgn0(api frontend.API, e fields_bls12377.E2) frontend.Variable {
+	a0Bits := api.ToBinary(e.A0)
+	a1Bits := api.ToBinary(e.A1)
+	a0IsZero := api.IsZero(e.A0)
+	return api.Select(a0IsZero, a1Bits[0], a0Bits[0])
+}
+
+// sqrtCandidateHint computes, for an E2 input gx, either a square root of
+// gx (when gx is a square) or a square root of nonResidueE2*gx
+// (otherwise), along with a flag recording which case holds.
+var sqrtCandidateHint = func(_ *big.Int, inputs []*big.Int, res []*big.Int) error {
+	var gx, zgx, root fptower.E2
+	gx.A0.SetBigInt(inputs[0])
+	gx.A1.SetBigInt(inputs[1])
+
+	if gx.Legendre() != -1 {
+		root.Sqrt(&gx)
+		res[0].SetUint64(1)
+	} else {
+		var z fptower.E2
+		z.A1.SetOne()
+		zgx.Mul(&z, &gx)
+		root.Sqrt(&zgx)
+		res[0].SetUint64(0)
+	}
+	root.A0.BigInt(res[1])
+	root.A1.BigInt(res[2])
+	return nil
+}
+
+func init() {
+	solver.RegisterHint(sqrtCandidateHint)
+}
+
+// sqrtCandidate returns the (isSquare, root) pair computed by
+// sqrtCandidateHint, constrained against gx: root²=gx when isSquare=1,
+// root²=nonResidueE2·gx otherwise.
+func sqrtCandidate(api frontend.API, gx fields_bls12377.E2) (frontend.Variable, fields_bls12377.E2) {
+	out, err := api.Compiler().NewHint(sqrtCandidateHint, 3, gx.A0, gx.A1)
+	if err != nil {
+		panic(err)
+	}
+	isSquare := out[0]
+	root := fields_bls12377.E2{A0: out[1], A1: out[2]}
+
+	var lhs, rhs, zgx fields_bls12377.E2
+	lhs.Square(api, root)
+	zgx.Mul(api, nonResidueE2, gx)
+	rhs.Select(api, isSquare, gx, zgx)
+	lhs.AssertIsEqual(api, rhs)
+
+	return isSquare, root
+}
+
+// hashToFieldHint OS2IP-decodes and reduces, modulo the base field, each
+// of the four equal-size big-endian byte chunks of the expand_message_xmd
+// output supplied in inputs, producing the four Fp limbs of the two E2
+// elements u0, u1 consumed by MapToCurveG2.
+var hashToFieldHint = func(p *big.Int, inputs []*big.Int, res []*big.Int) error {
+	l := len(inputs) / 4
+	for i := 0; i < 4; i++ {
+		acc := new(big.Int)
+		for j := 0; j < l; j++ {
+			acc.Lsh(acc, 8)
+			acc.Add(acc, inputs[i*l+j])
+		}
+		res[i] = acc.Mod(acc, p)
+	}
+	return nil
+}
+
+func init() {
+	solver.RegisterHint(hashToFieldHint)
+}
+
+const (
+	// sha256BlockBytes and sha256OutputBytes are SHA-256's block size
+	// (s_in_bytes) and digest size (b_in_bytes), per RFC 9380 section 5.3.1.
+	sha256BlockBytes  = 64
+	sha256OutputBytes = 32
+	// limbBytes (L) is the number of expand_message_xmd output bytes
+	// consumed per Fp limb: ceil((ceil(log2(p))+128)/8) for the ~377-bit
+	// BLS12-377 base field and a 128-bit security margin.
+	limbBytes = 64
+	// lenInBytes is expand_message_xmd's requested output length: one
+	// limbBytes-sized chunk per Fp coordinate of u0 and u1 (count=2, m=2).
+	lenInBytes = 4 * limbBytes
+	// ell is the number of SHA-256 blocks expand_message_xmd must produce
+	// to cover lenInBytes.
+	ell = (lenInBytes + sha256OutputBytes - 1) / sha256OutputBytes
+)
+
+// bytesToVars converts a compile-time []byte into circuit byte constants.
+func bytesToVars(bs []byte) []frontend.Variable {
+	vars := make([]frontend.Variable, len(bs))
+	for i, b := range bs {
+		vars[i] = b
+	}
+	return vars
+}
+
+// xorBytes returns the bytewise XOR of a and b (both circuit byte
+// variables of equal length).
+func xorBytes(api frontend.API, a, b []frontend.Variable) []frontend.Variable {
+	out := make([]frontend.Variable, len(a))
+	for i := range a {
+		aBits := api.ToBinary(a[i], 8)
+		bBits := api.ToBinary(b[i], 8)
+		xorBits := make([]frontend.Variable, 8)
+		for j := range xorBits {
+			xorBits[j] = api.Xor(aBits[j], bBits[j])
+		}
+		out[i] = api.FromBinary(xorBits...)
+	}
+	return out
+}
+
+// sha256Sum hashes the concatenation of the given circuit byte slices with
+// an in-circuit SHA-256 and returns the 32-byte digest.
+func sha256Sum(api frontend.API, parts ...[]frontend.Variable) ([]frontend.Variable, error) {
+	h, err := sha2.New(api)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(), nil
+}
+
+// hashToFieldE2 derives the two E2 elements u0, u1 consumed by
+// MapToCurveG2 from msg, using expand_message_xmd with an in-circuit
+// SHA-256 and the given domain separation tag, per RFC 9380 sections 5.2
+// and 5.3 (hash_to_field with count=2, m=2).
+func hashToFieldE2(api frontend.API, msg []frontend.Variable, dst []byte) (fields_bls12377.E2, fields_bls12377.E2, error) {
+	if len(dst) > 255 {
+		panic("sw_bls12377.hashToFieldE2: dst must be at most 255 bytes")
+	}
+	dstPrime := bytesToVars(append(append([]byte{}, dst...), byte(len(dst))))
+
+	zPad := bytesToVars(make([]byte, sha256BlockBytes))
+	libStr := bytesToVars([]byte{byte(lenInBytes >> 8), byte(lenInBytes)})
+	zero1 := bytesToVars([]byte{0})
+
+	b0, err := sha256Sum(api, zPad, msg, libStr, zero1, dstPrime)
+	if err != nil {
+		return fields_bls12377.E2{}, fields_bls12377.E2{}, err
+	}
+
+	uniformBytes := make([]frontend.Variable, 0, lenInBytes)
+	bPrev, err := sha256Sum(api, b0, bytesToVars([]byte{1}), dstPrime)
+	if err != nil {
+		return fields_bls12377.E2{}, fields_bls12377.E2{}, err
+	}
+	uniformBytes = append(uniformBytes, bPrev...)
+	for i := 2; i <= ell; i++ {
+		next, err := sha256Sum(api, xorBytes(api, b0, bPrev), bytesToVars([]byte{byte(i)}), dstPrime)
+		if err != nil {
+			return fields_bls12377.E2{}, fields_bls12377.E2{}, err
+		}
+		uniformBytes = append(uniformBytes, next...)
+		bPrev = next
+	}
+
+	limbs, err := api.Compiler().NewHint(hashToFieldHint, 4, uniformBytes...)
+	if err != nil {
+		return fields_bls12377.E2{}, fields_bls12377.E2{}, err
+	}
+
+	u0 := fields_bls12377.E2{A0: limbs[0], A1: limbs[1]}
+	u1 := fields_bls12377.E2{A0: limbs[2], A1: limbs[3]}
+	return u0, u1, nil
+}
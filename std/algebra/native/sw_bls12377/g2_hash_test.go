@@ -0,0 +1,99 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+const g2HashMsgLen = 4
+
+var g2HashDST = []byte("QUUX-V01-CS02-with-BLS12377G2_XMD:SHA-256_SVDW_RO_")
+
+// g2HashToCurveCircuit checks that HashToG2 lands in the r-torsion
+// subgroup and that hashing the same message twice is deterministic.
+type g2HashToCurveCircuit struct {
+	Msg [g2HashMsgLen]frontend.Variable
+}
+
+func (c *g2HashToCurveCircuit) Define(api frontend.API) error {
+	p0, err := HashToG2(api, c.Msg[:], g2HashDST)
+	if err != nil {
+		return err
+	}
+	p0.AssertIsInSubGroup(api)
+
+	p1, err := HashToG2(api, c.Msg[:], g2HashDST)
+	if err != nil {
+		return err
+	}
+	p0.P.AssertIsEqual(api, p1.P)
+
+	return nil
+}
+
+// TestHashToG2 checks that HashToG2 is deterministic and always lands in
+// the prime-order subgroup of the twist, for a couple of messages.
+func TestHashToG2(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	messages := [][g2HashMsgLen]frontend.Variable{
+		{0, 0, 0, 0},
+		{1, 2, 3, 4},
+		{255, 254, 253, 252},
+	}
+
+	for _, msg := range messages {
+		witness := g2HashToCurveCircuit{Msg: msg}
+		assert.SolvingSucceeded(&g2HashToCurveCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+	}
+}
+
+// g2HashDistinctCircuit checks that two distinct messages hash to two
+// distinct points, i.e. that MapToCurveG2's sign fix-up and the two
+// hash_to_field outputs are not accidentally collapsed.
+type g2HashDistinctCircuit struct {
+	MsgA, MsgB [g2HashMsgLen]frontend.Variable
+}
+
+func (c *g2HashDistinctCircuit) Define(api frontend.API) error {
+	pA, err := HashToG2(api, c.MsgA[:], g2HashDST)
+	if err != nil {
+		return err
+	}
+	pB, err := HashToG2(api, c.MsgB[:], g2HashDST)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(pA.P.X.A0, pB.P.X.A0)
+	return nil
+}
+
+func TestHashToG2Distinct(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	witness := g2HashDistinctCircuit{
+		MsgA: [g2HashMsgLen]frontend.Variable{0, 0, 0, 0},
+		MsgB: [g2HashMsgLen]frontend.Variable{1, 2, 3, 4},
+	}
+	assert.SolvingSucceeded(&g2HashDistinctCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
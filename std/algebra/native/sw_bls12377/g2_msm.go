@@ -0,0 +1,201 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/native/fields_bls12377"
+)
+
+// msmWindowSize is the width (in bits) of the windows used by
+// g2AffP.multiScalarMul: every term contributes a single lookup-and-add
+// per window, against a precomputed table of its 2^msmWindowSize small
+// multiples, instead of a chain of conditional doublings.
+const msmWindowSize = 4
+
+// MultiScalarMul sets P = ∑ scalars[i] * points[i] and returns P.
+//
+// It is substantially cheaper than calling ScalarMul in a loop and
+// accumulating the results: every scalar is first GLV-decomposed (as in
+// ScalarMul), doubling the number of terms but halving their bit length,
+// and then every term's contribution to a window is resolved with a
+// single table lookup (as in g2_fixedbase.go's lookupE2) against its own
+// precomputed small-multiples table, so the nWindows·msmWindowSize doublings of the
+// running accumulator are shared across every term instead of repeated
+// once per term as in a plain per-point double-and-add loop.
+func (P *G2Affine) MultiScalarMul(api frontend.API, points []G2Affine, scalars []frontend.Variable, opts ...algopts.AlgebraOption) *G2Affine {
+	ps := make([]g2AffP, len(points))
+	for i := range points {
+		ps[i] = points[i].P
+	}
+	P.P.multiScalarMul(api, ps, scalars, opts...)
+	return P
+}
+
+// multiScalarMul sets p to ∑ scalars[i] * points[i] and returns p. See
+// G2Affine.MultiScalarMul for a description of the algorithm.
+func (p *g2AffP) multiScalarMul(api frontend.API, points []g2AffP, scalars []frontend.Variable, opts ...algopts.AlgebraOption) *g2AffP {
+	if len(points) != len(scalars) {
+		panic("sw_bls12377.MultiScalarMul: points and scalars must have the same length")
+	}
+	zero := fields_bls12377.E2{A0: 0, A1: 0}
+	one := fields_bls12377.E2{A0: 1, A1: 0}
+	identity := g2ProjP{X: zero, Y: one, Z: zero}
+
+	if len(points) == 0 {
+		p.X, p.Y = zero, zero
+		return p
+	}
+
+	cfg, err := algopts.NewConfig(opts...)
+	if err != nil {
+		panic(err)
+	}
+	cc := getInnerCurveConfig(api.Compiler().Field())
+	nbits := cc.lambda.BitLen() + 1
+	nWindows := (nbits + msmWindowSize - 1) / msmWindowSize
+	nBuckets := 1 << msmWindowSize
+
+	// terms holds the 2*len(points) GLV half-size terms: for every input
+	// point P_i, terms[2i] = P_i (paired with s1_i) and terms[2i+1] =
+	// Φ(P_i) (paired with s2_i). digits[m] holds the big-endian
+	// msmWindowSize-bit windows of the corresponding half-scalar.
+	terms := make([]g2ProjP, 2*len(points))
+	digits := make([][]frontend.Variable, 2*len(points))
+
+	for i := range points {
+		if cfg.AssertSubgroupQ {
+			points[i].assertIsInSubGroup(api)
+		}
+		sd, err := api.Compiler().NewHint(DecomposeScalarG2, 3, scalars[i])
+		if err != nil {
+			// err is non-nil only for invalid number of inputs
+			panic(err)
+		}
+		s1, s2 := sd[0], sd[1]
+		// same relation as in varScalarMul: s1 + λ*s2 == s + k*r
+		api.AssertIsEqual(api.Add(s1, api.Mul(s2, cc.lambda)), api.Add(scalars[i], api.Mul(cc.fr, sd[2])))
+
+		var phiP g2AffP
+		cc.phi2(api, &phiP, &points[i])
+
+		if cfg.CompleteArithmetic {
+			// if points[i] is the point at infinity (encoded as the affine
+			// sentinel (0,0)), its projective form and that of Φ(points[i])
+			// must be the true identity (0,1,0), not (0,0,1).
+			isInf := api.And(points[i].X.IsZero(api), points[i].Y.IsZero(api))
+			var pP, phiPP g2ProjP
+			pP.FromAffine(api, points[i])
+			pP.Select(api, isInf, identity, pP)
+			phiPP.FromAffine(api, phiP)
+			phiPP.Select(api, isInf, identity, phiPP)
+			terms[2*i] = pP
+			terms[2*i+1] = phiPP
+		} else {
+			// fast path: points[i] (and hence Φ(points[i])) is assumed to
+			// never be the point at infinity.
+			terms[2*i].FromAffine(api, points[i])
+			terms[2*i+1].FromAffine(api, phiP)
+		}
+
+		digits[2*i] = windowDecompose(api, s1, nbits, msmWindowSize)
+		digits[2*i+1] = windowDecompose(api, s2, nbits, msmWindowSize)
+	}
+
+	// tables[m][k] = k·terms[m] for k=0..nBuckets-1 (tables[m][0] is the
+	// identity), built once per term and reused across every window.
+	tables := make([][]g2ProjP, len(terms))
+	for m := range terms {
+		tables[m] = buildMultiplesTable(api, terms[m], nBuckets, identity)
+	}
+
+	// digits[m] is big-endian (digits[m][0] is the most-significant
+	// window), so Horner's rule must consume it forward: double the
+	// accumulator by msmWindowSize bits, then add in the next
+	// (less-significant) window of every term.
+	acc := identity
+	for w := 0; w < nWindows; w++ {
+		acc.doubleN(api, &acc, msmWindowSize)
+		for m := range terms {
+			bits := api.ToBinary(digits[m][w], msmWindowSize)
+			acc.AddAssign(api, lookupProj(api, bits, tables[m]))
+		}
+	}
+
+	if cfg.CompleteArithmetic {
+		// the identity (Z=0) has no affine form: return the (0,0) sentinel.
+		acc.Select(api, acc.Z.IsZero(api), g2ProjP{X: zero, Y: zero, Z: one}, acc)
+	}
+	acc.toAffine(api, p)
+	return p
+}
+
+// buildMultiplesTable returns a table of n = 2^msmWindowSize small
+// multiples of base: table[0] is the identity and table[k] = k·base for
+// k=1..n-1, built with n-2 complete additions.
+func buildMultiplesTable(api frontend.API, base g2ProjP, n int, identity g2ProjP) []g2ProjP {
+	table := make([]g2ProjP, n)
+	table[0] = identity
+	table[1] = base
+	for k := 2; k < n; k++ {
+		table[k] = table[k-1]
+		table[k].AddAssign(api, base)
+	}
+	return table
+}
+
+// lookupProj is a power-of-two-sized multiplexer: it returns table[idx]
+// where idx is the integer represented by bits (bits[0] is the
+// least-significant bit), using a binary tree of g2ProjP selects. It
+// mirrors g2_fixedbase.go's lookupE2/lookupG2, generalized to projective
+// coordinates.
+func lookupProj(api frontend.API, bits []frontend.Variable, table []g2ProjP) g2ProjP {
+	if len(table) == 1 {
+		return table[0]
+	}
+	half := len(table) / 2
+	lo := lookupProj(api, bits[:len(bits)-1], table[:half])
+	hi := lookupProj(api, bits[:len(bits)-1], table[half:])
+	var res g2ProjP
+	res.Select(api, bits[len(bits)-1], hi, lo)
+	return res
+}
+
+// windowDecompose splits s (assumed to fit in nbits bits) into
+// ceil(nbits/w) big-endian windows of w bits each; the most-significant
+// window is zero-padded on the left when w does not divide nbits.
+func windowDecompose(api frontend.API, s frontend.Variable, nbits, w int) []frontend.Variable {
+	bits := api.ToBinary(s, nbits)
+	nWindows := (nbits + w - 1) / w
+	windows := make([]frontend.Variable, nWindows)
+	for i := 0; i < nWindows; i++ {
+		lo := i * w
+		hi := lo + w
+		if hi > nbits {
+			hi = nbits
+		}
+		acc := frontend.Variable(0)
+		coeff := 1
+		for j := lo; j < hi; j++ {
+			acc = api.Add(acc, api.Mul(bits[j], coeff))
+			coeff *= 2
+		}
+		windows[nWindows-1-i] = acc
+	}
+	return windows
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+const g2MsmNbTerms = 3
+
+type g2MultiScalarMulCircuit struct {
+	Points   [g2MsmNbTerms]G2Affine
+	Scalars  [g2MsmNbTerms]frontend.Variable
+	Expected G2Affine
+}
+
+func (c *g2MultiScalarMulCircuit) Define(api frontend.API) error {
+	var res G2Affine
+	res.MultiScalarMul(api, c.Points[:], c.Scalars[:])
+	res.P.AssertIsEqual(api, c.Expected.P)
+	return nil
+}
+
+// TestG2MultiScalarMul checks that the lookup-table-accumulation MSM
+// computes ∑ scalars[i]·points[i], agreeing with gnark-crypto's native
+// group operations.
+func TestG2MultiScalarMul(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+
+	points := make([]bls12377.G2Affine, g2MsmNbTerms)
+	scalars := make([]*big.Int, g2MsmNbTerms)
+	for i := 0; i < g2MsmNbTerms; i++ {
+		points[i].ScalarMultiplication(&g2Gen, big.NewInt(int64(2+i)))
+		scalars[i] = big.NewInt(int64(7 + 11*i))
+	}
+
+	var expected bls12377.G2Affine
+	for i := 0; i < g2MsmNbTerms; i++ {
+		var term bls12377.G2Affine
+		term.ScalarMultiplication(&points[i], scalars[i])
+		if i == 0 {
+			expected = term
+		} else {
+			expected.Add(&expected, &term)
+		}
+	}
+
+	var witness g2MultiScalarMulCircuit
+	for i := 0; i < g2MsmNbTerms; i++ {
+		witness.Points[i].P.Assign(&points[i])
+		witness.Scalars[i] = scalars[i]
+	}
+	witness.Expected.P.Assign(&expected)
+
+	assert.SolvingSucceeded(&g2MultiScalarMulCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
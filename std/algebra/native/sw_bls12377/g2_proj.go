@@ -0,0 +1,204 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/fields_bls12377"
+)
+
+// twistB is the b coefficient of the sextic twist of BLS12-377 (the curve
+// over which G2 is defined): Y² = X³ + twistB, with twistB ∈ E2.
+var twistB = fields_bls12377.E2{
+	A0: 0,
+	A1: "155198655607781456406391640216936120121836107652948796323930557600032281009004493664981332883744016074664192874906",
+}
+
+// twistB3 is 3*twistB, precomputed for the complete addition formulas below.
+var twistB3 = fields_bls12377.E2{
+	A0: 0,
+	A1: "206931540810375275208522186955914826829114810203931728431907410133376374678672658219975110511658688099552257166541",
+}
+
+// g2ProjP represents a point on the twist of BLS12-377 in projective
+// coordinates, i.e. (X,Y,Z) such that the corresponding affine point is
+// (X/Z, Y/Z). The point at infinity is represented by Z=0.
+//
+// Keeping the accumulator of a scalar multiplication in projective
+// coordinates avoids computing an E2 inversion (a Fermat-inverse hint plus
+// a multiplication check) at every step: the complete addition and
+// doubling formulas below only use multiplications and squarings. The
+// accumulator is converted back to affine coordinates once, at the very
+// end of the computation, via a single shared inversion.
+type g2ProjP struct {
+	X, Y, Z fields_bls12377.E2
+}
+
+// FromAffine sets p to Q (viewed as a projective point with Z=1) and
+// returns p.
+func (p *g2ProjP) FromAffine(api frontend.API, Q g2AffP) *g2ProjP {
+	p.X = Q.X
+	p.Y = Q.Y
+	p.Z = fields_bls12377.E2{A0: 1, A1: 0}
+	return p
+}
+
+// toAffine normalizes p and stores the corresponding affine point in res,
+// at the cost of one E2 inversion.
+func (p *g2ProjP) toAffine(api frontend.API, res *g2AffP) *g2AffP {
+	var zInv fields_bls12377.E2
+	one := fields_bls12377.E2{A0: 1, A1: 0}
+	zInv.DivUnchecked(api, one, p.Z)
+	res.X.Mul(api, p.X, zInv)
+	res.Y.Mul(api, p.Y, zInv)
+	return res
+}
+
+// Neg sets p to -p1 and returns p.
+func (p *g2ProjP) Neg(api frontend.API, p1 g2ProjP) *g2ProjP {
+	p.X = p1.X
+	p.Y.Neg(api, p1.Y)
+	p.Z = p1.Z
+	return p
+}
+
+// Select sets p to p1 if b=1, p2 if b=0, and returns it. b must be boolean
+// constrained.
+func (p *g2ProjP) Select(api frontend.API, b frontend.Variable, p1, p2 g2ProjP) *g2ProjP {
+	p.X.Select(api, b, p1.X, p2.X)
+	p.Y.Select(api, b, p1.Y, p2.Y)
+	p.Z.Select(api, b, p1.Z, p2.Z)
+	return p
+}
+
+// Double sets p to [2]p1 and returns p, using the complete doubling
+// formulas for short Weierstrass curves with a=0 (Renes-Costello-Batina,
+// Algorithm 9). It is valid for every input, including the point at
+// infinity, and costs 8 multiplications/squarings plus a multiplication
+// by the constant 3*twistB.
+func (p *g2ProjP) Double(api frontend.API, p1 g2ProjP) *g2ProjP {
+	var t0, t1, t2, x3, y3, z3 fields_bls12377.E2
+
+	t0.Square(api, p1.Y)
+	z3.Add(api, t0, t0)
+	z3.Add(api, z3, z3)
+	z3.Add(api, z3, z3)
+	t1.Mul(api, p1.Y, p1.Z)
+	t2.Square(api, p1.Z)
+	t2.Mul(api, t2, twistB3)
+	x3.Mul(api, t2, z3)
+	y3.Add(api, t0, t2)
+	z3.Mul(api, t1, z3)
+	t1.Add(api, t2, t2)
+	t2.Add(api, t1, t2)
+	t0.Sub(api, t0, t2)
+	y3.Mul(api, t0, y3)
+	y3.Add(api, x3, y3)
+	t1.Mul(api, p1.X, p1.Y)
+	x3.Mul(api, t0, t1)
+	x3.Add(api, x3, x3)
+
+	p.X = x3
+	p.Y = y3
+	p.Z = z3
+
+	return p
+}
+
+// AddAssign sets p to p+p1 and returns p, using the complete addition
+// formulas for short Weierstrass curves with a=0 (Renes-Costello-Batina,
+// Algorithm 7). It is valid for every pair of inputs, including when
+// p=p1 or one of them is the point at infinity, and costs 12
+// multiplications plus two multiplications by the constant 3*twistB.
+func (p *g2ProjP) AddAssign(api frontend.API, p1 g2ProjP) *g2ProjP {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fields_bls12377.E2
+
+	t0.Mul(api, p.X, p1.X)
+	t1.Mul(api, p.Y, p1.Y)
+	t2.Mul(api, p.Z, p1.Z)
+	t3.Add(api, p.X, p.Y)
+	t4.Add(api, p1.X, p1.Y)
+	t3.Mul(api, t3, t4)
+	t4.Add(api, t0, t1)
+	t3.Sub(api, t3, t4)
+	t4.Add(api, p.Y, p.Z)
+	x3.Add(api, p1.Y, p1.Z)
+	t4.Mul(api, t4, x3)
+	x3.Add(api, t1, t2)
+	t4.Sub(api, t4, x3)
+	x3.Add(api, p.X, p.Z)
+	y3.Add(api, p1.X, p1.Z)
+	x3.Mul(api, x3, y3)
+	y3.Add(api, t0, t2)
+	y3.Sub(api, x3, y3)
+	x3.Add(api, t0, t0)
+	t0.Add(api, x3, t0)
+	t2.Mul(api, t2, twistB3)
+	z3.Add(api, t1, t2)
+	t1.Sub(api, t1, t2)
+	y3.Mul(api, y3, twistB3)
+	x3.Mul(api, t4, y3)
+	t2.Mul(api, t3, t1)
+	x3.Sub(api, t2, x3)
+	y3.Mul(api, y3, t0)
+	t1.Mul(api, t1, z3)
+	y3.Add(api, t1, y3)
+	t0.Mul(api, t0, t3)
+	z3.Mul(api, z3, t4)
+	z3.Add(api, z3, t0)
+
+	p.X = x3
+	p.Y = y3
+	p.Z = z3
+
+	return p
+}
+
+// doubleN sets q to [2^n]q and returns it.
+func (p *g2ProjP) doubleN(api frontend.API, q *g2ProjP, n int) *g2ProjP {
+	pn := q
+	for s := 0; s < n; s++ {
+		pn.Double(api, *pn)
+	}
+	return pn
+}
+
+// AddAffine sets p to p+Q, where Q is given in affine coordinates
+// (equivalently, projective with Z=1), and returns p. It is a thin
+// wrapper over AddAssign and remains complete.
+func (p *g2ProjP) AddAffine(api frontend.API, Q g2AffP) *g2ProjP {
+	var q g2ProjP
+	q.FromAffine(api, Q)
+	return p.AddAssign(api, q)
+}
+
+// DoubleAndAdd sets p to [2]p1+p2 and returns p. Both operands are kept in
+// projective form, so no E2 inversion is performed.
+func (p *g2ProjP) DoubleAndAdd(api frontend.API, p1, p2 *g2ProjP) *g2ProjP {
+	p.Double(api, *p1)
+	p.AddAssign(api, *p2)
+	return p
+}
+
+// MixedDoubleAndAdd sets p to [2]p1+p2, where p2 is given in affine
+// coordinates, and returns p. It is used in the scalar-multiplication
+// loops of g2.go, where p2 is a table entry or a selected point.
+func (p *g2ProjP) MixedDoubleAndAdd(api frontend.API, p1 *g2ProjP, p2 *g2AffP) *g2ProjP {
+	p.Double(api, *p1)
+	p.AddAffine(api, *p2)
+	return p
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/fields_bls12377"
+)
+
+// AssertIsOnCurve asserts that p lies on the twist of BLS12-377, i.e. that
+// Y² = X³ + twistB.
+func (p *g2AffP) AssertIsOnCurve(api frontend.API) {
+	var lhs, rhs fields_bls12377.E2
+	lhs.Square(api, p.Y)
+	rhs.Square(api, p.X)
+	rhs.Mul(api, rhs, p.X)
+	rhs.Add(api, rhs, twistB)
+	lhs.AssertIsEqual(api, rhs)
+}
+
+// assertIsInSubGroup asserts that p is on-curve and belongs to the
+// prime-order subgroup of the twist of BLS12-377.
+//
+// Rather than the costly [r]P == 0 check, it uses the Bowe-Scott
+// endomorphism check: ψ(P) == [x]P, where x is the BLS12-377 seed and ψ
+// is the cheap psi endomorphism already used by the GLV scalar
+// multiplication. [x]P is computed with the existing scalarMulBySeed
+// routine, so the extra cost over an on-curve check is one seed-scalar
+// multiplication, one psi, and one equality assertion.
+func (p *g2AffP) assertIsInSubGroup(api frontend.API) {
+	p.AssertIsOnCurve(api)
+
+	var seedP, psiP g2AffP
+	seedP.scalarMulBySeed(api, p)
+	psiP.psi(api, p)
+	psiP.AssertIsEqual(api, seedP)
+}
+
+// AssertIsInSubGroup asserts that P is on-curve and belongs to the
+// prime-order subgroup of the twist of BLS12-377. It must be called on
+// every G2 point coming from an untrusted source (e.g. a BLS signature or
+// a KZG proof) before it is used in any other circuit computation.
+func (P *G2Affine) AssertIsInSubGroup(api frontend.API) {
+	P.P.assertIsInSubGroup(api)
+}
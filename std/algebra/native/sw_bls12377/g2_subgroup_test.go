@@ -0,0 +1,101 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fptower"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type g2SubgroupCheckCircuit struct {
+	P G2Affine
+}
+
+func (c *g2SubgroupCheckCircuit) Define(api frontend.API) error {
+	c.P.AssertIsInSubGroup(api)
+	return nil
+}
+
+// TestG2SubgroupCheckAccepts checks that the psi-endomorphism subgroup
+// check accepts a genuine r-torsion point.
+func TestG2SubgroupCheckAccepts(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+	var p bls12377.G2Affine
+	p.ScalarMultiplication(&g2Gen, big.NewInt(424242))
+
+	var witness g2SubgroupCheckCircuit
+	witness.P.P.Assign(&p)
+
+	assert.SolvingSucceeded(&g2SubgroupCheckCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+// TestG2SubgroupCheckRejects checks that the subgroup check rejects a
+// point that lies on the twist but outside its prime-order subgroup.
+func TestG2SubgroupCheckRejects(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	p := findNonSubgroupTwistPoint(t)
+
+	var witness g2SubgroupCheckCircuit
+	witness.P.P.Assign(&p)
+
+	assert.SolvingFailed(&g2SubgroupCheckCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+// findNonSubgroupTwistPoint returns a point on the twist of BLS12-377
+// (i.e. satisfying Y²=X³+twistB over Fp2) that is not in the r-torsion
+// subgroup, by trying successive small X until r·P != 0.
+func findNonSubgroupTwistPoint(t *testing.T) bls12377.G2Affine {
+	t.Helper()
+	r := fr377Modulus()
+	var b fptower.E2
+	b.A1.SetString("155198655607781456406391640216936120121836107652948796323930557600032281009004493664981332883744016074664192874906")
+
+	for x := int64(1); ; x++ {
+		var xE, gx, y fptower.E2
+		xE.A0.SetInt64(x)
+		gx.Square(&xE).Mul(&gx, &xE).Add(&gx, &b)
+		if gx.Legendre() != 1 {
+			continue
+		}
+		y.Sqrt(&gx)
+
+		var p bls12377.G2Affine
+		p.X, p.Y = xE, y
+
+		var pJac, rP bls12377.G2Jac
+		pJac.FromAffine(&p)
+		rP.ScalarMultiplication(&pJac, r)
+		if !rP.Z.IsZero() {
+			return p
+		}
+	}
+}
+
+func fr377Modulus() *big.Int {
+	r, _ := new(big.Int).SetString("8444461749428370424248824938781546531375899335154063827935233455917409239041", 10)
+	return r
+}
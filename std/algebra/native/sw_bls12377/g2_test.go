@@ -0,0 +1,165 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sw_bls12377
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/test"
+)
+
+// g2ScalarMulConstScalar is the compile-time-constant scalar used by
+// g2ScalarMulConstCircuit below, fixed so that the two circuit variants
+// (plain incomplete table and WithCompleteArithmetic) can share the same
+// expected value.
+const g2ScalarMulConstScalar = 123456789
+
+type g2AddAssignCircuit struct {
+	A, B, Expected G2Affine
+}
+
+func (c *g2AddAssignCircuit) Define(api frontend.API) error {
+	res := c.A.P
+	res.AddAssign(api, c.B.P)
+	res.AssertIsEqual(api, c.Expected.P)
+	return nil
+}
+
+// TestG2AddAssign checks that the affine g2AffP.AddAssign, which the scalar
+// multiplications still use outside their projective inner loops, agrees
+// with gnark-crypto's native G2 addition.
+func TestG2AddAssign(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+	var a, b, expected bls12377.G2Affine
+	a.ScalarMultiplication(&g2Gen, big.NewInt(3))
+	b.ScalarMultiplication(&g2Gen, big.NewInt(5))
+	expected.Add(&a, &b)
+
+	var witness g2AddAssignCircuit
+	witness.A.P.Assign(&a)
+	witness.B.P.Assign(&b)
+	witness.Expected.P.Assign(&expected)
+
+	assert.SolvingSucceeded(&g2AddAssignCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+type g2ScalarMulCircuit struct {
+	A        G2Affine
+	S        frontend.Variable
+	Expected G2Affine
+}
+
+func (c *g2ScalarMulCircuit) Define(api frontend.API) error {
+	var res g2AffP
+	res.ScalarMul(api, c.A.P, c.S)
+	res.AssertIsEqual(api, c.Expected.P)
+	return nil
+}
+
+// TestG2ScalarMul checks that the projective-accumulator inner loop of
+// varScalarMul still computes the same result as a native scalar
+// multiplication.
+func TestG2ScalarMul(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+	s := big.NewInt(123456789)
+	var expected bls12377.G2Affine
+	expected.ScalarMultiplication(&g2Gen, s)
+
+	var witness g2ScalarMulCircuit
+	witness.A.P.Assign(&g2Gen)
+	witness.S = s
+	witness.Expected.P.Assign(&expected)
+
+	assert.SolvingSucceeded(&g2ScalarMulCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+type g2ScalarMulConstCircuit struct {
+	A        G2Affine
+	Expected G2Affine
+	complete bool
+}
+
+func (c *g2ScalarMulConstCircuit) Define(api frontend.API) error {
+	var res g2AffP
+	if c.complete {
+		res.ScalarMul(api, c.A.P, g2ScalarMulConstScalar, algopts.WithCompleteArithmetic())
+	} else {
+		res.ScalarMul(api, c.A.P, g2ScalarMulConstScalar)
+	}
+	res.AssertIsEqual(api, c.Expected.P)
+	return nil
+}
+
+// TestG2ScalarMulConst checks that ScalarMul's constant-scalar branch
+// (constScalarMul), driven by a compile-time int rather than a
+// frontend.Variable, still agrees with a native scalar multiplication —
+// both with the default incomplete table and with
+// algopts.WithCompleteArithmetic().
+func TestG2ScalarMulConst(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+	var expected bls12377.G2Affine
+	expected.ScalarMultiplication(&g2Gen, big.NewInt(g2ScalarMulConstScalar))
+
+	var witness g2ScalarMulConstCircuit
+	witness.A.P.Assign(&g2Gen)
+	witness.Expected.P.Assign(&expected)
+
+	assert.SolvingSucceeded(&g2ScalarMulConstCircuit{complete: false}, &witness, test.WithCurves(ecc.BW6_761))
+	assert.SolvingSucceeded(&g2ScalarMulConstCircuit{complete: true}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+type g2ScalarMulBaseCircuit struct {
+	S        frontend.Variable
+	Expected G2Affine
+}
+
+func (c *g2ScalarMulBaseCircuit) Define(api frontend.API) error {
+	var res g2AffP
+	res.ScalarMulBase(api, c.S)
+	res.AssertIsEqual(api, c.Expected.P)
+	return nil
+}
+
+// TestG2ScalarMulBase checks that ScalarMulBase's projective-accumulator
+// rewrite still computes [s]·g2Gen, agreeing with a native scalar
+// multiplication against the G2 generator.
+func TestG2ScalarMulBase(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	_, _, _, g2Gen := bls12377.Generators()
+	s := big.NewInt(987654321)
+	var expected bls12377.G2Affine
+	expected.ScalarMultiplication(&g2Gen, s)
+
+	var witness g2ScalarMulBaseCircuit
+	witness.S = s
+	witness.Expected.P.Assign(&expected)
+
+	assert.SolvingSucceeded(&g2ScalarMulBaseCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}